@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeebo/bencode"
+)
+
+func TestGenerateTorrentsBuildsMultiFileTorrentForDirectory(t *testing.T) {
+	contentDir := t.TempDir()
+	torrentsDir := t.TempDir()
+
+	showDir := filepath.Join(contentDir, "show")
+	if err := os.Mkdir(showDir, 0755); err != nil {
+		t.Fatalf("creating show dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(showDir, "01.mkv"), []byte("episode one content"), 0644); err != nil {
+		t.Fatalf("writing 01.mkv: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(showDir, "02.mkv"), []byte("episode two content"), 0644); err != nil {
+		t.Fatalf("writing 02.mkv: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(contentDir, "standalone.mkv"), []byte("standalone content"), 0644); err != nil {
+		t.Fatalf("writing standalone.mkv: %v", err)
+	}
+
+	tm := NewGeneratingTorrentManager(torrentsDir, contentDir, "http://webseed.test/files")
+	if err := tm.GenerateTorrents(); err != nil {
+		t.Fatalf("GenerateTorrents: %v", err)
+	}
+
+	dirTorrentPath := filepath.Join(torrentsDir, generatedTorrentName("show"))
+	data, err := os.ReadFile(dirTorrentPath)
+	if err != nil {
+		t.Fatalf("reading generated directory torrent: %v", err)
+	}
+
+	var metaInfo TorrentMetaInfo
+	if err := bencode.DecodeBytes(data, &metaInfo); err != nil {
+		t.Fatalf("decoding generated directory torrent: %v", err)
+	}
+
+	if metaInfo.Info.Length != 0 {
+		t.Errorf("Info.Length = %d, want 0 for a multi-file torrent", metaInfo.Info.Length)
+	}
+	if len(metaInfo.Info.Files) != 2 {
+		t.Fatalf("Info.Files has %d entries, want 2", len(metaInfo.Info.Files))
+	}
+	if metaInfo.Info.Name != "show" {
+		t.Errorf("Info.Name = %q, want %q", metaInfo.Info.Name, "show")
+	}
+
+	want := sha1.Sum([]byte("episode one content" + "episode two content"))
+	if metaInfo.Info.Pieces != string(want[:]) {
+		t.Error("Info.Pieces does not match the SHA-1 of the concatenated file contents")
+	}
+
+	standaloneTorrentPath := filepath.Join(torrentsDir, generatedTorrentName("standalone.mkv"))
+	data, err = os.ReadFile(standaloneTorrentPath)
+	if err != nil {
+		t.Fatalf("reading generated standalone torrent: %v", err)
+	}
+	var standaloneMetaInfo TorrentMetaInfo
+	if err := bencode.DecodeBytes(data, &standaloneMetaInfo); err != nil {
+		t.Fatalf("decoding generated standalone torrent: %v", err)
+	}
+	if standaloneMetaInfo.Info.Length != int64(len("standalone content")) {
+		t.Errorf("Info.Length = %d, want %d for a single-file torrent", standaloneMetaInfo.Info.Length, len("standalone content"))
+	}
+}
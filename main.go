@@ -1,36 +1,115 @@
 package main
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/germainlefebvre4/webseed2torznab/pkg/downloader"
+	"github.com/germainlefebvre4/webseed2torznab/pkg/tracker"
 	"github.com/gorilla/mux"
 	"github.com/zeebo/bencode"
 )
 
+// maxUploadSize caps the size of multipart torrent uploads accepted by the API.
+const maxUploadSize = 32 << 20 // 32 MiB
+
+// defaultCategory is the Torznab category assigned to torrents that carry no
+// [cat=] tag, don't sit under a recognised category folder and don't
+// otherwise look like TV episodes.
+const defaultCategory = 7000
+
+var (
+	catTagRe   = regexp.MustCompile(`(?i)\[cat=(\d+)\]`)
+	imdbTagRe  = regexp.MustCompile(`(?i)\[imdb=(tt\d+)\]`)
+	tvdbTagRe  = regexp.MustCompile(`(?i)\[tvdb=(\d+)\]`)
+	seasonEpRe = regexp.MustCompile(`(?i)S(\d{1,2})E(\d{1,3})`)
+)
+
+// parseNameTags extracts category/episode metadata embedded in a torrent
+// filename. category is 0 if no explicit "[cat=...]" tag was present, so
+// callers can fall back to a directory-derived or season/episode-derived
+// category before defaulting to defaultCategory.
+func parseNameTags(filename string) (category int, imdbID, tvdbID string, season, episode int) {
+	if m := catTagRe.FindStringSubmatch(filename); m != nil {
+		if v, err := strconv.Atoi(m[1]); err == nil {
+			category = v
+		}
+	}
+	if m := imdbTagRe.FindStringSubmatch(filename); m != nil {
+		imdbID = m[1]
+	}
+	if m := tvdbTagRe.FindStringSubmatch(filename); m != nil {
+		tvdbID = m[1]
+	}
+	if m := seasonEpRe.FindStringSubmatch(filename); m != nil {
+		season, _ = strconv.Atoi(m[1])
+		episode, _ = strconv.Atoi(m[2])
+	}
+
+	return category, imdbID, tvdbID, season, episode
+}
+
+// categoryFromPath derives a Torznab category from a torrent's directory
+// path relative to the torrents root (e.g. "Movies/Action" -> 2000,
+// "TV/Shows" -> 5000), so the folder a torrent lives in becomes its default
+// category tag. It returns 0 if no known top-level folder name matches.
+func categoryFromPath(relDir string) int {
+	if relDir == "" || relDir == "." {
+		return 0
+	}
+
+	top := strings.ToLower(strings.Split(filepath.ToSlash(relDir), "/")[0])
+	switch top {
+	case "movies", "movie", "films":
+		return 2000
+	case "tv", "shows", "series":
+		return 5000
+	default:
+		return 0
+	}
+}
+
 // TorrentInfo represents the parsed torrent file information
 type TorrentInfo struct {
-	Name        string    `json:"name"`
-	InfoHash    string    `json:"info_hash"`
-	Size        int64     `json:"size"`
-	Files       []File    `json:"files"`
-	WebSeeds    []string  `json:"web_seeds"`
-	CreatedBy   string    `json:"created_by,omitempty"`
-	CreatedDate time.Time `json:"created_date"`
-	Comment     string    `json:"comment,omitempty"`
-	FilePath    string    `json:"file_path"`
+	Name         string    `json:"name"`
+	InfoHash     string    `json:"info_hash"`
+	Size         int64     `json:"size"`
+	Files        []File    `json:"files"`
+	WebSeeds     []string  `json:"web_seeds"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedDate  time.Time `json:"created_date"`
+	Comment      string    `json:"comment,omitempty"`
+	FilePath     string    `json:"file_path"`
+	Category     int       `json:"category"`
+	IMDBID       string    `json:"imdb_id,omitempty"`
+	TVDBID       string    `json:"tvdb_id,omitempty"`
+	Season       int       `json:"season,omitempty"`
+	Episode      int       `json:"episode,omitempty"`
+	Grabs        int       `json:"grabs"`
+	AnnounceURLs []string  `json:"announce_urls,omitempty"`
+	CategoryPath string    `json:"category_path,omitempty"`
 }
 
 // File represents a file within the torrent
@@ -69,16 +148,26 @@ type FileDict struct {
 type TorznabResponse struct {
 	XMLName   xml.Name       `xml:"rss"`
 	Version   string         `xml:"version,attr"`
+	AtomNS    string         `xml:"xmlns:atom,attr"`
 	TorznabNS string         `xml:"xmlns:torznab,attr"`
 	Channel   TorznabChannel `xml:"channel"`
 }
 
 // TorznabChannel represents the channel element in Torznab response
 type TorznabChannel struct {
-	Title       string        `xml:"title"`
-	Description string        `xml:"description"`
-	Link        string        `xml:"link"`
-	Items       []TorznabItem `xml:"item"`
+	Title       string          `xml:"title"`
+	Description string          `xml:"description"`
+	Link        string          `xml:"link"`
+	AtomLink    TorznabAtomLink `xml:"atom:link"`
+	Items       []TorznabItem   `xml:"item"`
+}
+
+// TorznabAtomLink represents the <atom:link rel="self"> self-reference
+// Torznab validators expect on the channel.
+type TorznabAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
 }
 
 // TorznabItem represents an item in the Torznab response
@@ -106,45 +195,572 @@ type TorznabAttr struct {
 	Value string `xml:"value,attr"`
 }
 
+// Index is a concurrency-safe, info-hash-keyed store of loaded torrents. A
+// secondary index of lowercased name tokens backs name search: each query
+// token does a prefix scan over the distinct tokens seen so far, rather than
+// SearchTorrentsFiltered falling back to a linear strings.Contains scan over
+// every torrent. Putting a torrent whose info hash already exists overwrites
+// the previous entry, which de-duplicates identical torrents discovered in
+// more than one subfolder.
+type Index struct {
+	mu      sync.RWMutex
+	byHash  map[string]TorrentInfo
+	byToken map[string]map[string]struct{} // token -> set of info hashes
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		byHash:  make(map[string]TorrentInfo),
+		byToken: make(map[string]map[string]struct{}),
+	}
+}
+
+// nameTokenRe splits torrent names into searchable lowercase tokens.
+var nameTokenRe = regexp.MustCompile(`[a-z0-9]+`)
+
+func nameTokens(name string) []string {
+	return nameTokenRe.FindAllString(strings.ToLower(name), -1)
+}
+
+// Put inserts or replaces (by info hash) a torrent in the index.
+func (idx *Index) Put(t TorrentInfo) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if existing, ok := idx.byHash[t.InfoHash]; ok {
+		idx.untokenizeLocked(existing)
+	}
+
+	idx.byHash[t.InfoHash] = t
+	for _, tok := range nameTokens(t.Name) {
+		set, ok := idx.byToken[tok]
+		if !ok {
+			set = make(map[string]struct{})
+			idx.byToken[tok] = set
+		}
+		set[t.InfoHash] = struct{}{}
+	}
+}
+
+// RemoveByPath drops the torrent backed by filePath, if any, and returns
+// whether an entry was removed.
+func (idx *Index) RemoveByPath(filePath string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for hash, t := range idx.byHash {
+		if t.FilePath == filePath {
+			idx.untokenizeLocked(t)
+			delete(idx.byHash, hash)
+			return true
+		}
+	}
+	return false
+}
+
+// untokenizeLocked removes t's name tokens from byToken. Callers must hold
+// idx.mu.
+func (idx *Index) untokenizeLocked(t TorrentInfo) {
+	for _, tok := range nameTokens(t.Name) {
+		set := idx.byToken[tok]
+		delete(set, t.InfoHash)
+		if len(set) == 0 {
+			delete(idx.byToken, tok)
+		}
+	}
+}
+
+// All returns every indexed torrent, in no particular order.
+func (idx *Index) All() []TorrentInfo {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	out := make([]TorrentInfo, 0, len(idx.byHash))
+	for _, t := range idx.byHash {
+		out = append(out, t)
+	}
+	return out
+}
+
+// Get looks up a single torrent by info hash.
+func (idx *Index) Get(infoHash string) (TorrentInfo, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	t, ok := idx.byHash[infoHash]
+	return t, ok
+}
+
+// Search returns torrents whose name has, for every token in query (split
+// into lowercase alphanumeric runs the same way names are), a matching name
+// token with that prefix. A query with no tokens returns every torrent. It
+// uses the token index instead of scanning every torrent's name.
+func (idx *Index) Search(query string) []TorrentInfo {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	tokens := nameTokens(query)
+	if len(tokens) == 0 {
+		out := make([]TorrentInfo, 0, len(idx.byHash))
+		for _, t := range idx.byHash {
+			out = append(out, t)
+		}
+		return out
+	}
+
+	var matches map[string]struct{}
+	for _, tok := range tokens {
+		hashesForPrefix := make(map[string]struct{})
+		for indexedTok, hashes := range idx.byToken {
+			if strings.HasPrefix(indexedTok, tok) {
+				for h := range hashes {
+					hashesForPrefix[h] = struct{}{}
+				}
+			}
+		}
+		if len(hashesForPrefix) == 0 {
+			return []TorrentInfo{}
+		}
+
+		if matches == nil {
+			matches = hashesForPrefix
+			continue
+		}
+		for h := range matches {
+			if _, ok := hashesForPrefix[h]; !ok {
+				delete(matches, h)
+			}
+		}
+	}
+
+	out := make([]TorrentInfo, 0, len(matches))
+	for h := range matches {
+		out = append(out, idx.byHash[h])
+	}
+	return out
+}
+
 // TorrentManager handles torrent operations
 type TorrentManager struct {
 	torrentsDir string
-	torrents    []TorrentInfo
+	contentDir  string
+	webseedBase string
+	index       atomic.Pointer[Index]
+
+	grabsMu sync.Mutex
+	grabs   map[string]int
 }
 
 // NewTorrentManager creates a new TorrentManager
 func NewTorrentManager(torrentsDir string) *TorrentManager {
-	return &TorrentManager{
+	tm := &TorrentManager{torrentsDir: torrentsDir}
+	tm.index.Store(NewIndex())
+	return tm
+}
+
+// NewGeneratingTorrentManager creates a TorrentManager that, in addition to
+// loading .torrent files from torrentsDir, can synthesize torrents on the fly
+// from raw files under contentDir. webseedBase is the URL prefix (e.g.
+// http://host:port/files) used to build each generated torrent's url-list.
+func NewGeneratingTorrentManager(torrentsDir, contentDir, webseedBase string) *TorrentManager {
+	tm := &TorrentManager{
 		torrentsDir: torrentsDir,
-		torrents:    make([]TorrentInfo, 0),
+		contentDir:  contentDir,
+		webseedBase: strings.TrimSuffix(webseedBase, "/"),
 	}
+	tm.index.Store(NewIndex())
+	return tm
 }
 
-// LoadTorrents scans the torrents directory and loads all torrent files
+// LoadTorrents performs a full recursive rescan of the torrents directory,
+// replacing the in-memory index wholesale. It is the manual counterpart to
+// the fsnotify-driven incremental updates from WatchTorrentsDir; call it via
+// /api/refresh or at startup.
 func (tm *TorrentManager) LoadTorrents() error {
-	files, err := ioutil.ReadDir(tm.torrentsDir)
+	tm.loadGrabs()
+
+	newIndex := NewIndex()
+	count := 0
+
+	err := filepath.WalkDir(tm.torrentsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".torrent") {
+			return nil
+		}
+
+		torrentInfo, err := tm.parseTorrentFile(path)
+		if err != nil {
+			log.Printf("Error parsing torrent file %s: %v", path, err)
+			return nil
+		}
+		newIndex.Put(*torrentInfo)
+		count++
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("error reading torrents directory: %v", err)
 	}
 
-	tm.torrents = make([]TorrentInfo, 0)
+	tm.index.Store(newIndex)
 
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(strings.ToLower(file.Name()), ".torrent") {
-			torrentPath := filepath.Join(tm.torrentsDir, file.Name())
-			torrentInfo, err := tm.parseTorrentFile(torrentPath)
-			if err != nil {
-				log.Printf("Error parsing torrent file %s: %v", file.Name(), err)
-				continue
+	log.Printf("Loaded %d torrent files", count)
+	return nil
+}
+
+// WatchTorrentsDir watches the torrents directory tree for Create, Write,
+// Remove and Rename events and applies them to the in-memory index
+// incrementally, without a full rescan. It is the primary way the index
+// stays up to date; LoadTorrents (via /api/refresh) remains available as a
+// manual full rescan. WatchTorrentsDir blocks until ctx is cancelled.
+func (tm *TorrentManager) WatchTorrentsDir(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := tm.addWatchesRecursively(watcher, tm.torrentsDir); err != nil {
+		return fmt.Errorf("error watching torrents directory: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			tm.handleWatchEvent(watcher, event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Watcher error: %v", err)
+		}
+	}
+}
+
+// addWatchesRecursively registers root and every subdirectory under it with
+// watcher, since fsnotify does not watch directory trees recursively on its
+// own.
+func (tm *TorrentManager) addWatchesRecursively(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// handleWatchEvent applies a single fsnotify event to the index: new
+// directories get watched, new/modified .torrent files are (re)parsed and
+// indexed, and removed/renamed-away files are dropped from the index.
+func (tm *TorrentManager) handleWatchEvent(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		tm.RemoveTorrent(event.Name)
+		return
+	}
+
+	if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+		return
+	}
+
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		return
+	}
+
+	if info.IsDir() {
+		if event.Op&fsnotify.Create != 0 {
+			if err := tm.addWatchesRecursively(watcher, event.Name); err != nil {
+				log.Printf("Error watching new directory %s: %v", event.Name, err)
+			}
+		}
+		return
+	}
+
+	if !strings.HasSuffix(strings.ToLower(event.Name), ".torrent") {
+		return
+	}
+
+	if _, err := tm.AddTorrent(event.Name); err != nil {
+		log.Printf("Error indexing %s: %v", event.Name, err)
+	}
+}
+
+// grabsPath returns the location of the persisted per-infohash grab counter
+// file inside the torrents directory.
+func (tm *TorrentManager) grabsPath() string {
+	return filepath.Join(tm.torrentsDir, "grabs.json")
+}
+
+// loadGrabs reads the persisted grab counters from disk. A missing file is
+// treated as "no grabs yet" rather than an error.
+func (tm *TorrentManager) loadGrabs() {
+	tm.grabsMu.Lock()
+	defer tm.grabsMu.Unlock()
+
+	tm.grabs = make(map[string]int)
+
+	data, err := ioutil.ReadFile(tm.grabsPath())
+	if err != nil {
+		return
+	}
+
+	if err := json.Unmarshal(data, &tm.grabs); err != nil {
+		log.Printf("Warning: could not parse grabs file: %v", err)
+		tm.grabs = make(map[string]int)
+	}
+}
+
+// IncrementGrabs bumps the grab counter for infoHash, persists it to disk and
+// updates the in-memory torrent entry so subsequent searches reflect it.
+func (tm *TorrentManager) IncrementGrabs(infoHash string) int {
+	tm.grabsMu.Lock()
+	tm.grabs[infoHash]++
+	count := tm.grabs[infoHash]
+	data, err := json.Marshal(tm.grabs)
+	tm.grabsMu.Unlock()
+
+	if err != nil {
+		log.Printf("Warning: could not marshal grabs: %v", err)
+		return count
+	}
+	if err := ioutil.WriteFile(tm.grabsPath(), data, 0644); err != nil {
+		log.Printf("Warning: could not persist grabs: %v", err)
+	}
+
+	if torrent, ok := tm.index.Load().Get(infoHash); ok {
+		torrent.Grabs = count
+		tm.index.Load().Put(torrent)
+	}
+
+	return count
+}
+
+// defaultPieceLength is the piece size used for generated torrents when the
+// content is small enough not to need scaling up.
+const defaultPieceLength = 256 * 1024
+
+// calculatePieceLength picks a BitTorrent piece length for a file of the
+// given size, scaling up for larger files so the pieces list doesn't grow
+// unreasonably long (the same heuristic most torrent creation tools use).
+func calculatePieceLength(size int64) int64 {
+	pieceLength := int64(defaultPieceLength)
+	for pieceLength*1024 < size && pieceLength < 16*1024*1024 {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// GenerateTorrents scans the top level of contentDir and synthesizes an
+// in-memory TorrentMetaInfo for each entry that isn't already cached, writing
+// the generated .torrent bytes to torrentsDir so they can be served via the
+// existing /torrent/{filename} route. A top-level file becomes a single-file
+// torrent; a top-level directory becomes one multi-file torrent spanning
+// every file beneath it, so a whole directory can be published as a single
+// swarm.
+func (tm *TorrentManager) GenerateTorrents() error {
+	if tm.contentDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(tm.contentDir)
+	if err != nil {
+		return fmt.Errorf("error reading content directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		relPath := entry.Name()
+		path := filepath.Join(tm.contentDir, relPath)
+
+		cacheName := generatedTorrentName(relPath)
+		cachePath := filepath.Join(tm.torrentsDir, cacheName)
+
+		if _, statErr := os.Stat(cachePath); statErr == nil {
+			if _, parseErr := tm.AddTorrent(cachePath); parseErr != nil {
+				log.Printf("Error indexing generated torrent %s: %v", cacheName, parseErr)
 			}
-			tm.torrents = append(tm.torrents, *torrentInfo)
+			continue
+		}
+
+		var data []byte
+		if entry.IsDir() {
+			data, err = tm.synthesizeDirTorrent(path, relPath)
+		} else {
+			var info os.FileInfo
+			info, err = entry.Info()
+			if err == nil {
+				data, err = tm.synthesizeTorrent(path, relPath, info.Size())
+			}
+		}
+		if err != nil {
+			log.Printf("Error generating torrent for %s: %v", relPath, err)
+			continue
+		}
+
+		if err := ioutil.WriteFile(cachePath, data, 0644); err != nil {
+			log.Printf("Error caching generated torrent for %s: %v", relPath, err)
+			continue
+		}
+
+		if _, err := tm.AddTorrent(cachePath); err != nil {
+			log.Printf("Error indexing generated torrent %s: %v", cacheName, err)
 		}
 	}
 
-	log.Printf("Loaded %d torrent files", len(tm.torrents))
 	return nil
 }
 
+// generatedTorrentName derives a stable, filesystem-safe .torrent cache
+// filename from a file's path relative to contentDir.
+func generatedTorrentName(relPath string) string {
+	slug := strings.ReplaceAll(filepath.ToSlash(relPath), "/", "_")
+	return slug + ".torrent"
+}
+
+// synthesizeTorrent builds a single-file TorrentMetaInfo for the raw file at
+// path, hashing it into SHA-1 pieces and pointing its url-list at the
+// configured web-seed base, then returns the bencoded bytes.
+func (tm *TorrentManager) synthesizeTorrent(path, relPath string, size int64) ([]byte, error) {
+	pieceLength := calculatePieceLength(size)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pieces, err := hashPieces(f, pieceLength)
+	if err != nil {
+		return nil, err
+	}
+
+	webSeedURL := tm.webseedBase + "/" + filepath.ToSlash(relPath)
+
+	metaInfo := TorrentMetaInfo{
+		CreatedBy:    "webseed2torznab",
+		CreationDate: time.Now().Unix(),
+		Info: InfoDict{
+			Name:        filepath.Base(relPath),
+			Length:      size,
+			PieceLength: pieceLength,
+			Pieces:      pieces,
+		},
+		URLList: []string{webSeedURL},
+	}
+
+	return bencode.EncodeBytes(metaInfo)
+}
+
+// synthesizeDirTorrent builds a multi-file TorrentMetaInfo spanning every
+// file under dirPath, hashing the concatenation of their contents (in the
+// same order as info.files, as BitTorrent's multi-file layout treats them as
+// one logical byte stream) into SHA-1 pieces. Its single web seed points at
+// the directory's own base URL; BEP-19 clients append each file's info.files
+// path to it, same as they would for relPath under a single-file torrent.
+func (tm *TorrentManager) synthesizeDirTorrent(dirPath, relPath string) ([]byte, error) {
+	var files []FileDict
+	var paths []string
+	var totalSize int64
+
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, FileDict{
+			Length: info.Size(),
+			Path:   strings.Split(filepath.ToSlash(rel), "/"),
+		})
+		paths = append(paths, path)
+		totalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found under %s", relPath)
+	}
+
+	pieceLength := calculatePieceLength(totalSize)
+
+	pieces, err := hashPiecesMulti(paths, pieceLength)
+	if err != nil {
+		return nil, err
+	}
+
+	webSeedURL := tm.webseedBase + "/" + filepath.ToSlash(relPath)
+
+	metaInfo := TorrentMetaInfo{
+		CreatedBy:    "webseed2torznab",
+		CreationDate: time.Now().Unix(),
+		Info: InfoDict{
+			Name:        filepath.Base(relPath),
+			Files:       files,
+			PieceLength: pieceLength,
+			Pieces:      pieces,
+		},
+		URLList: []string{webSeedURL},
+	}
+
+	return bencode.EncodeBytes(metaInfo)
+}
+
+// hashPieces reads r in pieceLength chunks and returns the concatenated
+// SHA-1 digests, as expected in the info.pieces field.
+func hashPieces(r io.Reader, pieceLength int64) (string, error) {
+	var pieces strings.Builder
+	buf := make([]byte, pieceLength)
+
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			sum := sha1.Sum(buf[:n])
+			pieces.Write(sum[:])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return pieces.String(), nil
+}
+
+// hashPiecesMulti hashes the concatenation of the files at paths, in order,
+// via hashPieces.
+func hashPiecesMulti(paths []string, pieceLength int64) (string, error) {
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+
+	return hashPieces(io.MultiReader(readers...), pieceLength)
+}
+
 // calculateInfoHash calculates the info hash from torrent data
 func calculateInfoHash(torrentData []byte) (string, error) {
 	var torrent map[string]interface{}
@@ -216,19 +832,98 @@ func (tm *TorrentManager) parseTorrentFile(filePath string) (*TorrentInfo, error
 	// Extract web seeds from URLList
 	webSeeds := extractWebSeeds(metaInfo.URLList)
 
+	// Category/episode metadata is conveyed by filename tags, e.g.
+	// "[cat=5000][tvdb=12345]Show.S01E02.torrent"
+	category, imdbID, tvdbID, season, episode := parseNameTags(filepath.Base(filePath))
+
+	// Fall back to the folder the torrent lives in, then to a season/episode
+	// guess, then to defaultCategory, in that priority order.
+	categoryPath := ""
+	if relDir, err := filepath.Rel(tm.torrentsDir, filepath.Dir(filePath)); err == nil && relDir != "." {
+		categoryPath = filepath.ToSlash(relDir)
+	}
+	if category == 0 {
+		category = categoryFromPath(categoryPath)
+	}
+	if category == 0 && season != 0 {
+		category = 5000
+	}
+	if category == 0 {
+		category = defaultCategory
+	}
+
+	tm.grabsMu.Lock()
+	grabs := tm.grabs[infoHash]
+	tm.grabsMu.Unlock()
+
+	announceURLs := extractAnnounceURLs(metaInfo.Announce, metaInfo.AnnounceList)
+
 	return &TorrentInfo{
-		Name:        metaInfo.Info.Name,
-		InfoHash:    infoHash,
-		Size:        totalSize,
-		Files:       files,
-		WebSeeds:    webSeeds,
-		CreatedBy:   metaInfo.CreatedBy,
-		CreatedDate: createdDate,
-		Comment:     metaInfo.Comment,
-		FilePath:    filePath,
+		Name:         metaInfo.Info.Name,
+		InfoHash:     infoHash,
+		Size:         totalSize,
+		Files:        files,
+		WebSeeds:     webSeeds,
+		CreatedBy:    metaInfo.CreatedBy,
+		CreatedDate:  createdDate,
+		Comment:      metaInfo.Comment,
+		FilePath:     filePath,
+		Category:     category,
+		IMDBID:       imdbID,
+		TVDBID:       tvdbID,
+		Season:       season,
+		Episode:      episode,
+		Grabs:        grabs,
+		AnnounceURLs: announceURLs,
+		CategoryPath: categoryPath,
 	}, nil
 }
 
+// extractAnnounceURLs flattens the primary announce URL and the
+// announce-list (a list of lists, per BEP-12) into a single deduplicated
+// slice of tracker URLs.
+func extractAnnounceURLs(announce string, announceList interface{}) []string {
+	seen := make(map[string]bool)
+	var urls []string
+
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+
+	add(announce)
+
+	if tiers, ok := announceList.([]interface{}); ok {
+		for _, tier := range tiers {
+			tierURLs, ok := tier.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, u := range tierURLs {
+				if s, ok := u.(string); ok {
+					add(s)
+				}
+			}
+		}
+	}
+
+	return urls
+}
+
+// atoiDefault parses s as an int, returning def if s is empty or invalid.
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
 // extractWebSeeds extracts web seed URLs from various possible formats
 func extractWebSeeds(urlList interface{}) []string {
 	if urlList == nil {
@@ -258,22 +953,84 @@ func extractWebSeeds(urlList interface{}) []string {
 
 // GetTorrents returns all loaded torrents
 func (tm *TorrentManager) GetTorrents() []TorrentInfo {
-	return tm.torrents
+	return tm.index.Load().All()
+}
+
+// AddTorrent parses the .torrent file at filePath and inserts (or replaces, by
+// info hash) it into the in-memory index without rescanning the whole
+// directory. It is used by the upload API and the fsnotify watcher so
+// changes show up in Torznab/JSON results immediately.
+func (tm *TorrentManager) AddTorrent(filePath string) (*TorrentInfo, error) {
+	torrentInfo, err := tm.parseTorrentFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tm.index.Load().Put(*torrentInfo)
+	return torrentInfo, nil
+}
+
+// RemoveTorrent drops the torrent backed by filePath from the in-memory
+// index. It does not touch the file on disk.
+func (tm *TorrentManager) RemoveTorrent(filePath string) {
+	tm.index.Load().RemoveByPath(filePath)
 }
 
 // SearchTorrents searches torrents by query
 func (tm *TorrentManager) SearchTorrents(query string) []TorrentInfo {
-	if query == "" {
-		return tm.torrents
-	}
+	return tm.SearchTorrentsFiltered(SearchFilter{Query: query})
+}
+
+// SearchFilter narrows SearchTorrentsFiltered results. Zero values mean
+// "don't filter on this field". Limit of 0 means "no limit".
+type SearchFilter struct {
+	Query   string
+	Cat     int
+	IMDBID  string
+	TVDBID  string
+	Season  int
+	Episode int
+	Limit   int
+	Offset  int
+}
+
+// SearchTorrentsFiltered applies the Torznab q/cat/imdbid/tvdbid/season/ep
+// filters and limit/offset pagination used by handleTorznabSearch.
+func (tm *TorrentManager) SearchTorrentsFiltered(filter SearchFilter) []TorrentInfo {
+	candidates := tm.index.Load().Search(filter.Query)
 
 	var results []TorrentInfo
-	query = strings.ToLower(query)
+	for _, torrent := range candidates {
+		if filter.Cat != 0 && torrent.Category != filter.Cat {
+			continue
+		}
+		if filter.IMDBID != "" && !strings.EqualFold(torrent.IMDBID, filter.IMDBID) {
+			continue
+		}
+		if filter.TVDBID != "" && torrent.TVDBID != filter.TVDBID {
+			continue
+		}
+		if filter.Season != 0 && torrent.Season != filter.Season {
+			continue
+		}
+		if filter.Episode != 0 && torrent.Episode != filter.Episode {
+			continue
+		}
+		results = append(results, torrent)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedDate.After(results[j].CreatedDate)
+	})
 
-	for _, torrent := range tm.torrents {
-		if strings.Contains(strings.ToLower(torrent.Name), query) {
-			results = append(results, torrent)
+	if filter.Offset > 0 {
+		if filter.Offset >= len(results) {
+			return []TorrentInfo{}
 		}
+		results = results[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(results) {
+		results = results[:filter.Limit]
 	}
 
 	return results
@@ -281,8 +1038,10 @@ func (tm *TorrentManager) SearchTorrents(query string) []TorrentInfo {
 
 // APIServer handles HTTP requests
 type APIServer struct {
-	torrentManager *TorrentManager
-	baseURL        string
+	torrentManager   *TorrentManager
+	baseURL          string
+	scraper          *tracker.Scraper
+	downloaderClient downloader.Client
 }
 
 // NewAPIServer creates a new API server
@@ -290,6 +1049,7 @@ func NewAPIServer(torrentManager *TorrentManager, baseURL string) *APIServer {
 	return &APIServer{
 		torrentManager: torrentManager,
 		baseURL:        baseURL,
+		scraper:        tracker.NewScraper(tracker.DefaultTTL),
 	}
 }
 
@@ -308,16 +1068,13 @@ func (s *APIServer) handleTorrentsJSON(w http.ResponseWriter, r *http.Request) {
 
 // handleTorznabAPI handles Torznab API requests
 func (s *APIServer) handleTorznabAPI(w http.ResponseWriter, r *http.Request) {
-	t := r.URL.Query().Get("t")
-	query := r.URL.Query().Get("q")
-
-	switch t {
+	switch r.URL.Query().Get("t") {
 	case "caps":
 		s.handleTorznabCaps(w, r)
-	case "search":
-		s.handleTorznabSearch(w, r, query)
+	case "tvsearch", "movie", "search", "":
+		s.handleTorznabSearch(w, r)
 	default:
-		s.handleTorznabSearch(w, r, query)
+		s.handleTorznabSearch(w, r)
 	}
 }
 
@@ -329,11 +1086,19 @@ func (s *APIServer) handleTorznabCaps(w http.ResponseWriter, r *http.Request) {
   <limits max="100" default="100"/>
   <registration available="no" open="no"/>
   <searching>
-    <search available="yes" supportedParams="q"/>
+    <search available="yes" supportedParams="q,cat,limit,offset"/>
+    <tv-search available="yes" supportedParams="q,cat,limit,offset,tvdbid,season,ep"/>
+    <movie-search available="yes" supportedParams="q,cat,limit,offset,imdbid"/>
   </searching>
   <categories>
-    <category id="2000" name="Movies"/>
-    <category id="5000" name="TV"/>
+    <category id="2000" name="Movies">
+      <subcat id="2040" name="Movies/HD"/>
+      <subcat id="2045" name="Movies/UHD"/>
+    </category>
+    <category id="5000" name="TV">
+      <subcat id="5040" name="TV/HD"/>
+      <subcat id="5045" name="TV/UHD"/>
+    </category>
     <category id="7000" name="Other"/>
   </categories>
 </caps>`
@@ -342,42 +1107,98 @@ func (s *APIServer) handleTorznabCaps(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(capsXML))
 }
 
-// handleTorznabSearch handles search requests for Torznab API
-func (s *APIServer) handleTorznabSearch(w http.ResponseWriter, r *http.Request, query string) {
-	torrents := s.torrentManager.SearchTorrents(query)
+// handleTorznabSearch handles t=search/tvsearch/movie requests for the
+// Torznab API, parsing cat/limit/offset/imdbid/tvdbid/season/ep from the
+// query string.
+func (s *APIServer) handleTorznabSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := SearchFilter{
+		Query:   q.Get("q"),
+		IMDBID:  strings.TrimPrefix(q.Get("imdbid"), "tt"),
+		TVDBID:  q.Get("tvdbid"),
+		Cat:     atoiDefault(q.Get("cat"), 0),
+		Season:  atoiDefault(q.Get("season"), 0),
+		Episode: atoiDefault(q.Get("ep"), 0),
+		Limit:   atoiDefault(q.Get("limit"), 100),
+		Offset:  atoiDefault(q.Get("offset"), 0),
+	}
+	if filter.IMDBID != "" {
+		filter.IMDBID = "tt" + filter.IMDBID
+	}
+
+	torrents := s.torrentManager.SearchTorrentsFiltered(filter)
+	selfLink := fmt.Sprintf("%s/api/torznab?%s", s.baseURL, r.URL.RawQuery)
+	push := q.Get("push") == "1"
 
 	response := TorznabResponse{
 		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
 		TorznabNS: "http://torznab.com/schemas/2015/feed",
 		Channel: TorznabChannel{
 			Title:       "WebSeed2Torznab",
 			Description: "Local torrent files with web seeds",
 			Link:        s.baseURL,
+			AtomLink:    TorznabAtomLink{Href: selfLink, Rel: "self", Type: "application/rss+xml"},
 			Items:       make([]TorznabItem, 0),
 		},
 	}
 
 	for _, torrent := range torrents {
+		filename := filepath.Base(torrent.FilePath)
+		downloadLink := fmt.Sprintf("%s/torrent/%s", s.baseURL, url.QueryEscape(filename))
+		if push {
+			downloadLink = fmt.Sprintf("%s/api/grab/%s", s.baseURL, url.QueryEscape(filename))
+		}
+
+		seeders, peers := "1", "1"
+		stats, ok := s.scraper.Get(torrent.InfoHash)
+		if ok {
+			seeders = strconv.Itoa(stats.Seeders)
+			peers = strconv.Itoa(stats.Seeders + stats.Leechers)
+		}
+
 		item := TorznabItem{
 			Title:       torrent.Name,
 			Description: torrent.Comment,
-			Link:        fmt.Sprintf("%s/torrent/%s", s.baseURL, url.QueryEscape(filepath.Base(torrent.FilePath))),
+			Link:        downloadLink,
 			GUID:        torrent.InfoHash,
 			PubDate:     torrent.CreatedDate.Format(time.RFC1123Z),
 			Size:        torrent.Size,
 			Enclosure: TorznabEnclosure{
-				URL:    fmt.Sprintf("%s/torrent/%s", s.baseURL, url.QueryEscape(filepath.Base(torrent.FilePath))),
+				URL:    downloadLink,
 				Length: torrent.Size,
 				Type:   "application/x-bittorrent",
 			},
 			Attributes: []TorznabAttr{
-				{Name: "category", Value: "7000"},
+				{Name: "category", Value: strconv.Itoa(torrent.Category)},
 				{Name: "size", Value: strconv.FormatInt(torrent.Size, 10)},
-				{Name: "seeders", Value: "1"},
-				{Name: "peers", Value: "1"},
+				{Name: "infohash", Value: torrent.InfoHash},
+				{Name: "seeders", Value: seeders},
+				{Name: "peers", Value: peers},
+				{Name: "grabs", Value: strconv.Itoa(torrent.Grabs)},
+				{Name: "downloadvolumefactor", Value: "0"},
+				{Name: "uploadvolumefactor", Value: "1"},
+				{Name: "webseed_alive", Value: strconv.FormatBool(stats.WebSeedAlive)},
 			},
 		}
 
+		if torrent.IMDBID != "" {
+			item.Attributes = append(item.Attributes, TorznabAttr{Name: "imdb", Value: strings.TrimPrefix(torrent.IMDBID, "tt")})
+		}
+		if torrent.TVDBID != "" {
+			item.Attributes = append(item.Attributes, TorznabAttr{Name: "tvdbid", Value: torrent.TVDBID})
+		}
+		if torrent.Season != 0 {
+			item.Attributes = append(item.Attributes, TorznabAttr{Name: "season", Value: strconv.Itoa(torrent.Season)})
+		}
+		if torrent.Episode != 0 {
+			item.Attributes = append(item.Attributes, TorznabAttr{Name: "episode", Value: strconv.Itoa(torrent.Episode)})
+		}
+		if torrent.CategoryPath != "" {
+			item.Attributes = append(item.Attributes, TorznabAttr{Name: "category_path", Value: torrent.CategoryPath})
+		}
+
 		if len(torrent.WebSeeds) > 0 {
 			item.Attributes = append(item.Attributes, TorznabAttr{
 				Name:  "magneturl",
@@ -388,10 +1209,188 @@ func (s *APIServer) handleTorznabSearch(w http.ResponseWriter, r *http.Request,
 		response.Channel.Items = append(response.Channel.Items, item)
 	}
 
-	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Type", "application/rss+xml")
+	fmt.Fprint(w, xml.Header)
 	xml.NewEncoder(w).Encode(response)
 }
 
+// handleTorrentUpload accepts a multipart .torrent upload, splices in any
+// requested web-seed URLs and writes the result to torrentsDir. An optional
+// category field is embedded as a "[cat=N]" filename tag (the same
+// convention parseNameTags reads back out) if the filename doesn't already
+// carry one.
+func (s *APIServer) handleTorrentUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing upload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("torrent")
+	if err != nil {
+		http.Error(w, "Missing torrent file field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(strings.ToLower(header.Filename), ".torrent") {
+		http.Error(w, "Invalid file type", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var metaInfo TorrentMetaInfo
+	if err := bencode.DecodeBytes(data, &metaInfo); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid torrent file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	webSeeds := r.MultipartForm.Value["ws"]
+	if len(webSeeds) > 0 {
+		data, err = spliceWebSeeds(data, webSeeds)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error injecting web seeds: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	filename := filepath.Base(header.Filename)
+	if category := r.MultipartForm.Value["category"]; len(category) > 0 && category[0] != "" {
+		if _, err := strconv.Atoi(category[0]); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid category: %v", err), http.StatusBadRequest)
+			return
+		}
+		if cat, _, _, _, _ := parseNameTags(filename); cat == 0 {
+			filename = fmt.Sprintf("[cat=%s]%s", category[0], filename)
+		}
+	}
+
+	torrentPath := filepath.Join(s.torrentManager.torrentsDir, filename)
+	if err := ioutil.WriteFile(torrentPath, data, 0644); err != nil {
+		http.Error(w, fmt.Sprintf("Error saving torrent: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	torrentInfo, err := s.torrentManager.AddTorrent(torrentPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error indexing torrent: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"torrent": torrentInfo,
+	})
+}
+
+// handleTorrentDelete removes a previously uploaded .torrent file from both
+// disk and the in-memory index.
+func (s *APIServer) handleTorrentDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	filename := vars["filename"]
+
+	if !strings.HasSuffix(strings.ToLower(filename), ".torrent") {
+		http.Error(w, "Invalid file type", http.StatusBadRequest)
+		return
+	}
+
+	torrentPath := filepath.Join(s.torrentManager.torrentsDir, filename)
+	if _, err := os.Stat(torrentPath); os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := os.Remove(torrentPath); err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting torrent: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.torrentManager.RemoveTorrent(torrentPath)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Torrent deleted successfully",
+	})
+}
+
+// handleGrab pushes a previously published .torrent file to the configured
+// download client (qBittorrent or Transmission), enabling one-click
+// "send to client" flows from *arr apps.
+func (s *APIServer) handleGrab(w http.ResponseWriter, r *http.Request) {
+	if s.downloaderClient == nil {
+		http.Error(w, "No downloader configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	vars := mux.Vars(r)
+	filename := vars["filename"]
+
+	if !strings.HasSuffix(strings.ToLower(filename), ".torrent") {
+		http.Error(w, "Invalid file type", http.StatusBadRequest)
+		return
+	}
+
+	torrentPath := filepath.Join(s.torrentManager.torrentsDir, filename)
+	if _, err := os.Stat(torrentPath); os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+
+	category := r.URL.Query().Get("category")
+	if err := s.downloaderClient.AddTorrent(r.Context(), torrentPath, category); err != nil {
+		http.Error(w, fmt.Sprintf("Error pushing torrent to downloader: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	if infoHash, err := calculateInfoHashFromFile(torrentPath); err == nil {
+		s.torrentManager.IncrementGrabs(infoHash)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Torrent pushed to downloader",
+	})
+}
+
+// spliceWebSeeds merges extraURLs into the url-list key of a .torrent file,
+// de-duplicating against any web seeds already present, and returns the
+// re-encoded bytes.
+func spliceWebSeeds(torrentData []byte, extraURLs []string) ([]byte, error) {
+	var metaInfo TorrentMetaInfo
+	if err := bencode.DecodeBytes(torrentData, &metaInfo); err != nil {
+		return nil, fmt.Errorf("error decoding bencode: %v", err)
+	}
+
+	existing := extractWebSeeds(metaInfo.URLList)
+
+	seen := make(map[string]bool, len(existing))
+	merged := make([]string, 0, len(existing)+len(extraURLs))
+	for _, u := range existing {
+		if !seen[u] {
+			seen[u] = true
+			merged = append(merged, u)
+		}
+	}
+	for _, u := range extraURLs {
+		u = strings.TrimSpace(u)
+		if u != "" && !seen[u] {
+			seen[u] = true
+			merged = append(merged, u)
+		}
+	}
+
+	metaInfo.URLList = merged
+
+	return bencode.EncodeBytes(metaInfo)
+}
+
 // handleTorrentDownload serves torrent files for download
 func (s *APIServer) handleTorrentDownload(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -410,11 +1409,77 @@ func (s *APIServer) handleTorrentDownload(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if infoHash, err := calculateInfoHashFromFile(torrentPath); err == nil {
+		s.torrentManager.IncrementGrabs(infoHash)
+	}
+
 	w.Header().Set("Content-Type", "application/x-bittorrent")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 	http.ServeFile(w, r, torrentPath)
 }
 
+// calculateInfoHashFromFile reads filePath and computes its BitTorrent info
+// hash, for callers that only have a path on disk (e.g. grab tracking).
+func calculateInfoHashFromFile(filePath string) (string, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return calculateInfoHash(data)
+}
+
+// handleServeFile serves a raw file out of the content directory so BEP-19
+// web-seed clients can fetch the pieces of a generated torrent.
+func (s *APIServer) handleServeFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	relPath := vars["path"]
+
+	if s.torrentManager.contentDir == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	filePath := filepath.Join(s.torrentManager.contentDir, filepath.FromSlash(relPath))
+	if !strings.HasPrefix(filePath, filepath.Clean(s.torrentManager.contentDir)+string(os.PathSeparator)) {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	http.ServeFile(w, r, filePath)
+}
+
+// handleStats returns the live tracker/web-seed stats for a single torrent,
+// identified by info hash.
+func (s *APIServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	infoHash := vars["infohash"]
+
+	stats, ok := s.scraper.Get(infoHash)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// scrapeTargets builds the tracker.Target list the background scraper works
+// from, one per currently loaded torrent.
+func scrapeTargets(tm *TorrentManager) []tracker.Target {
+	torrents := tm.GetTorrents()
+	targets := make([]tracker.Target, 0, len(torrents))
+	for _, t := range torrents {
+		targets = append(targets, tracker.Target{
+			InfoHash:     t.InfoHash,
+			AnnounceURLs: t.AnnounceURLs,
+			WebSeeds:     t.WebSeeds,
+			Size:         t.Size,
+		})
+	}
+	return targets
+}
+
 // handleRefresh handles refresh requests to reload torrents
 func (s *APIServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	err := s.torrentManager.LoadTorrents()
@@ -423,6 +1488,11 @@ func (s *APIServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.torrentManager.GenerateTorrents(); err != nil {
+		http.Error(w, fmt.Sprintf("Error regenerating torrents: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":  "success",
@@ -447,22 +1517,58 @@ func main() {
 		baseURL = envBaseURL
 	}
 
+	contentDir := os.Getenv("CONTENT_DIR")
+	webseedBase := os.Getenv("WEBSEED_BASE")
+	if webseedBase == "" {
+		webseedBase = baseURL + "/files"
+	}
+
 	// Initialize torrent manager
-	torrentManager := NewTorrentManager(torrentsDir)
+	var torrentManager *TorrentManager
+	if contentDir != "" {
+		torrentManager = NewGeneratingTorrentManager(torrentsDir, contentDir, webseedBase)
+	} else {
+		torrentManager = NewTorrentManager(torrentsDir)
+	}
+
 	err := torrentManager.LoadTorrents()
 	if err != nil {
 		log.Fatalf("Error loading torrents: %v", err)
 	}
 
+	if contentDir != "" {
+		if err := torrentManager.GenerateTorrents(); err != nil {
+			log.Fatalf("Error generating torrents from content directory: %v", err)
+		}
+	}
+
 	// Initialize API server
 	apiServer := NewAPIServer(torrentManager, baseURL)
 
+	if downloaderKind := os.Getenv("DOWNLOADER_KIND"); downloaderKind != "" {
+		client, err := downloader.New(downloader.Config{
+			Kind:     downloaderKind,
+			URL:      os.Getenv("DOWNLOADER_URL"),
+			Username: os.Getenv("DOWNLOADER_USER"),
+			Password: os.Getenv("DOWNLOADER_PASS"),
+		})
+		if err != nil {
+			log.Fatalf("Error configuring downloader: %v", err)
+		}
+		apiServer.downloaderClient = client
+		log.Printf("Pushing grabs to %s downloader at %s", downloaderKind, os.Getenv("DOWNLOADER_URL"))
+	}
+
 	// Setup routes
 	r := mux.NewRouter()
 
 	// JSON API endpoints
 	r.HandleFunc("/api/torrents", apiServer.handleTorrentsJSON).Methods("GET")
+	r.HandleFunc("/api/torrents", apiServer.handleTorrentUpload).Methods("POST")
+	r.HandleFunc("/api/torrents/{filename}", apiServer.handleTorrentDelete).Methods("DELETE")
 	r.HandleFunc("/api/refresh", apiServer.handleRefresh).Methods("POST")
+	r.HandleFunc("/api/stats/{infohash}", apiServer.handleStats).Methods("GET")
+	r.HandleFunc("/api/grab/{filename}", apiServer.handleGrab).Methods("GET", "POST")
 
 	// Torznab API endpoints
 	r.HandleFunc("/api/torznab", apiServer.handleTorznabAPI).Methods("GET")
@@ -470,6 +1576,9 @@ func main() {
 	// Torrent file download
 	r.HandleFunc("/torrent/{filename}", apiServer.handleTorrentDownload).Methods("GET")
 
+	// Raw file serving for web-seed clients of generated torrents
+	r.HandleFunc("/files/{path:.*}", apiServer.handleServeFile).Methods("GET")
+
 	// Health check
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -498,10 +1607,18 @@ func main() {
     <ul>
         <li><strong>GET /api/torrents</strong> - List all torrents in JSON format
             <br><em>Query parameters: ?q=search_term</em></li>
-        <li><strong>POST /api/refresh</strong> - Refresh torrent list</li>
+        <li><strong>POST /api/torrents</strong> - Upload a .torrent file
+            <br><em>Multipart form fields: torrent=&lt;file&gt;, ws=web_seed_url (repeatable), category</em></li>
+        <li><strong>DELETE /api/torrents/{filename}</strong> - Delete an uploaded torrent file</li>
+        <li><strong>POST /api/refresh</strong> - Force a full rescan of the torrents directory
+            <br><em>The directory is also watched continuously, so this is rarely needed</em></li>
+        <li><strong>GET /api/stats/{infohash}</strong> - Live tracker/web-seed stats for a torrent</li>
+        <li><strong>POST /api/grab/{filename}</strong> - Push a torrent to the configured download client
+            <br><em>Query parameters: ?category=</em></li>
         <li><strong>GET /api/torznab</strong> - Torznab API endpoint
-            <br><em>Query parameters: ?t=search&q=search_term or ?t=caps</em></li>
+            <br><em>Query parameters: ?t=search|tvsearch|movie|caps&q=&cat=&limit=&offset=&imdbid=&tvdbid=&season=&ep=&push=1</em></li>
         <li><strong>GET /torrent/{filename}</strong> - Download torrent file</li>
+        <li><strong>GET /files/{path}</strong> - Serve a raw file for web-seed clients of generated torrents</li>
         <li><strong>GET /health</strong> - Health check</li>
     </ul>
     
@@ -531,11 +1648,27 @@ curl -X POST ` + baseURL + `/api/refresh
 
 	log.Printf("Starting WebSeed2Torznab server on port %s", port)
 	log.Printf("Serving torrents from: %s", torrentsDir)
+	if contentDir != "" {
+		log.Printf("Generating torrents from content directory: %s (web seed base: %s)", contentDir, webseedBase)
+	}
 	log.Printf("Base URL: %s", baseURL)
 	log.Printf("API endpoints:")
 	log.Printf("  JSON API: %s/api/torrents", baseURL)
 	log.Printf("  Torznab API: %s/api/torznab", baseURL)
 	log.Printf("  Torznab Caps: %s/api/torznab?t=caps", baseURL)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go apiServer.scraper.Run(ctx, tracker.DefaultTTL, func() []tracker.Target {
+		return scrapeTargets(torrentManager)
+	})
+
+	go func() {
+		if err := torrentManager.WatchTorrentsDir(ctx); err != nil {
+			log.Printf("Torrents directory watcher stopped: %v", err)
+		}
+	}()
+
 	log.Fatal(http.ListenAndServe(":"+port, r))
 }
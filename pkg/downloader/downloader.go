@@ -0,0 +1,36 @@
+// Package downloader pushes .torrent files to a download client so Torznab
+// consumers (Sonarr, Radarr, Prowlarr, ...) can trigger a "send to client"
+// grab instead of just downloading the .torrent file themselves.
+package downloader
+
+import (
+	"context"
+	"fmt"
+)
+
+// Client adds a .torrent file to a download client's queue.
+type Client interface {
+	// AddTorrent pushes the .torrent file at torrentPath (optionally tagged
+	// with category) to the download client.
+	AddTorrent(ctx context.Context, torrentPath, category string) error
+}
+
+// Config describes how to reach a configured download client.
+type Config struct {
+	Kind     string // "qbit" or "transmission"
+	URL      string
+	Username string
+	Password string
+}
+
+// New builds a Client for cfg.Kind.
+func New(cfg Config) (Client, error) {
+	switch cfg.Kind {
+	case "qbit", "qbittorrent":
+		return NewQBittorrentClient(cfg.URL, cfg.Username, cfg.Password), nil
+	case "transmission":
+		return NewTransmissionClient(cfg.URL, cfg.Username, cfg.Password), nil
+	default:
+		return nil, fmt.Errorf("unknown downloader kind: %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,120 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QBittorrentClient talks to the qBittorrent Web API.
+type QBittorrentClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewQBittorrentClient creates a client for the qBittorrent Web API at
+// baseURL (e.g. http://localhost:8080).
+func NewQBittorrentClient(baseURL, username, password string) *QBittorrentClient {
+	return &QBittorrentClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{},
+	}
+}
+
+// login authenticates against /api/v2/auth/login and returns the value of
+// the SID session cookie from the response. The http.Client has no cookie
+// jar, so callers must replay the SID on subsequent requests themselves.
+func (c *QBittorrentClient) login(ctx context.Context) (string, error) {
+	form := url.Values{
+		"username": {c.username},
+		"password": {c.password},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("qbittorrent login failed: %s", resp.Status)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			return cookie.Value, nil
+		}
+	}
+	return "", fmt.Errorf("qbittorrent login did not return a session cookie")
+}
+
+// AddTorrent uploads the .torrent file at torrentPath via
+// /api/v2/torrents/add, tagging it with category if non-empty.
+func (c *QBittorrentClient) AddTorrent(ctx context.Context, torrentPath, category string) error {
+	sid, err := c.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(torrentPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("torrents", filepath.Base(torrentPath))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+
+	if category != "" {
+		if err := writer.WriteField("category", category); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/torrents/add", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: "SID", Value: sid})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("qbittorrent add-torrent failed: %s", resp.Status)
+	}
+	return nil
+}
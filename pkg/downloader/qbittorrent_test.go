@@ -0,0 +1,75 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQBittorrentClient_AddTorrent(t *testing.T) {
+	var gotCategory string
+	var sawAuthCookie bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			if r.Method != http.MethodPost {
+				t.Errorf("login: got method %s, want POST", r.Method)
+			}
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "test-sid"})
+			w.WriteHeader(http.StatusOK)
+		case "/api/v2/torrents/add":
+			if c, err := r.Cookie("SID"); err == nil && c.Value == "test-sid" {
+				sawAuthCookie = true
+			}
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("add-torrent: parsing multipart form: %v", err)
+			}
+			gotCategory = r.FormValue("category")
+			if _, _, err := r.FormFile("torrents"); err != nil {
+				t.Errorf("add-torrent: missing torrents file field: %v", err)
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(torrentPath, []byte("d8:announce...e"), 0644); err != nil {
+		t.Fatalf("writing fixture torrent: %v", err)
+	}
+
+	client := NewQBittorrentClient(server.URL, "user", "pass")
+	if err := client.AddTorrent(context.Background(), torrentPath, "movies"); err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+
+	if !sawAuthCookie {
+		t.Error("add-torrent request did not carry the session cookie from login")
+	}
+	if gotCategory != "movies" {
+		t.Errorf("category = %q, want %q", gotCategory, "movies")
+	}
+}
+
+func TestQBittorrentClient_AddTorrent_LoginFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(torrentPath, []byte("d8:announce...e"), 0644); err != nil {
+		t.Fatalf("writing fixture torrent: %v", err)
+	}
+
+	client := NewQBittorrentClient(server.URL, "user", "wrong")
+	if err := client.AddTorrent(context.Background(), torrentPath, ""); err == nil {
+		t.Fatal("expected an error from a failed login, got nil")
+	}
+}
@@ -0,0 +1,114 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TransmissionClient talks to the Transmission RPC API.
+type TransmissionClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewTransmissionClient creates a client for the Transmission RPC endpoint at
+// baseURL (e.g. http://localhost:9091/transmission/rpc).
+func NewTransmissionClient(baseURL, username, password string) *TransmissionClient {
+	return &TransmissionClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{},
+	}
+}
+
+type transmissionRequest struct {
+	Method    string      `json:"method"`
+	Arguments interface{} `json:"arguments"`
+}
+
+type transmissionResponse struct {
+	Result string `json:"result"`
+}
+
+// AddTorrent pushes the .torrent file at torrentPath to Transmission via the
+// torrent-add RPC method, retrying once with the session ID Transmission
+// returns on its initial 409 Conflict response.
+func (c *TransmissionClient) AddTorrent(ctx context.Context, torrentPath, category string) error {
+	data, err := os.ReadFile(torrentPath)
+	if err != nil {
+		return err
+	}
+
+	args := map[string]interface{}{
+		"metainfo": base64.StdEncoding.EncodeToString(data),
+	}
+	if category != "" {
+		args["labels"] = []string{category}
+	}
+
+	reqBody := transmissionRequest{Method: "torrent-add", Arguments: args}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+
+	resp, sessionID, err := c.call(ctx, payload, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		resp, _, err = c.call(ctx, payload, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	var result transmissionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.Result != "success" {
+		return fmt.Errorf("transmission torrent-add failed: %s", result.Result)
+	}
+	return nil
+}
+
+// call performs a single RPC request, returning the response, the session ID
+// from the X-Transmission-Session-Id header of a 409 Conflict response (empty
+// for any other status), and any transport error. On a 409 the response body
+// is already closed; otherwise the caller is responsible for closing it.
+func (c *TransmissionClient) call(ctx context.Context, payload []byte, sessionID string) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set("X-Transmission-Session-Id", sessionID)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode == http.StatusConflict {
+		defer resp.Body.Close()
+		return resp, resp.Header.Get("X-Transmission-Session-Id"), nil
+	}
+
+	return resp, "", nil
+}
@@ -0,0 +1,97 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransmissionClient_AddTorrent(t *testing.T) {
+	var gotLabels []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req transmissionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request body: %v", err)
+		}
+		if req.Method != "torrent-add" {
+			t.Errorf("method = %q, want torrent-add", req.Method)
+		}
+		args, _ := req.Arguments.(map[string]interface{})
+		if labels, ok := args["labels"].([]interface{}); ok {
+			for _, l := range labels {
+				gotLabels = append(gotLabels, l.(string))
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transmissionResponse{Result: "success"})
+	}))
+	defer server.Close()
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(torrentPath, []byte("d8:announce...e"), 0644); err != nil {
+		t.Fatalf("writing fixture torrent: %v", err)
+	}
+
+	client := NewTransmissionClient(server.URL, "", "")
+	if err := client.AddTorrent(context.Background(), torrentPath, "tv"); err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+
+	if len(gotLabels) != 1 || gotLabels[0] != "tv" {
+		t.Errorf("labels = %v, want [tv]", gotLabels)
+	}
+}
+
+func TestTransmissionClient_AddTorrent_RetriesOnSessionConflict(t *testing.T) {
+	const sessionID = "test-session-id"
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Transmission-Session-Id") != sessionID {
+			w.Header().Set("X-Transmission-Session-Id", sessionID)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transmissionResponse{Result: "success"})
+	}))
+	defer server.Close()
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(torrentPath, []byte("d8:announce...e"), 0644); err != nil {
+		t.Fatalf("writing fixture torrent: %v", err)
+	}
+
+	client := NewTransmissionClient(server.URL, "", "")
+	if err := client.AddTorrent(context.Background(), torrentPath, ""); err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 (initial 409 + retry with session id)", requests)
+	}
+}
+
+func TestTransmissionClient_AddTorrent_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transmissionResponse{Result: "duplicate torrent"})
+	}))
+	defer server.Close()
+
+	torrentPath := filepath.Join(t.TempDir(), "test.torrent")
+	if err := os.WriteFile(torrentPath, []byte("d8:announce...e"), 0644); err != nil {
+		t.Fatalf("writing fixture torrent: %v", err)
+	}
+
+	client := NewTransmissionClient(server.URL, "", "")
+	if err := client.AddTorrent(context.Background(), torrentPath, ""); err == nil {
+		t.Fatal("expected an error for a non-success result, got nil")
+	}
+}
@@ -0,0 +1,318 @@
+// Package tracker scrapes BitTorrent trackers (BEP-15 UDP scrape and the
+// common HTTP /scrape convention) for live seeder/leecher counts, and
+// HEAD-probes web-seed URLs, so callers can show real swarm health instead of
+// hard-coded placeholders.
+package tracker
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeebo/bencode"
+)
+
+// DefaultTTL is how long a scraped result is considered fresh before it is
+// re-queried.
+const DefaultTTL = 15 * time.Minute
+
+// TorrentStats holds the live swarm/web-seed health for a single torrent.
+type TorrentStats struct {
+	InfoHash     string    `json:"info_hash"`
+	Seeders      int       `json:"seeders"`
+	Leechers     int       `json:"leechers"`
+	Downloaded   int       `json:"downloaded"`
+	WebSeedAlive bool      `json:"webseed_alive"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// Target describes what to scrape for a single torrent.
+type Target struct {
+	InfoHash     string
+	AnnounceURLs []string
+	WebSeeds     []string
+	Size         int64 // expected file size in bytes, for validating web-seed Content-Length
+}
+
+// Scraper periodically refreshes TorrentStats for a set of targets and
+// caches them for DefaultTTL (or a caller-supplied TTL).
+type Scraper struct {
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	stats map[string]TorrentStats
+}
+
+// NewScraper creates a Scraper that caches results for ttl. A ttl of 0 uses
+// DefaultTTL.
+func NewScraper(ttl time.Duration) *Scraper {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Scraper{
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		stats:      make(map[string]TorrentStats),
+	}
+}
+
+// Get returns the cached stats for infoHash, if present and not yet expired.
+func (s *Scraper) Get(infoHash string) (TorrentStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats, ok := s.stats[infoHash]
+	if !ok || time.Since(stats.FetchedAt) > s.ttl {
+		return TorrentStats{}, false
+	}
+	return stats, true
+}
+
+// Run scrapes targets() on every tick of interval until ctx is cancelled,
+// refreshing the cache in place. It blocks until ctx.Done() fires.
+func (s *Scraper) Run(ctx context.Context, interval time.Duration, targets func() []Target) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.refreshAll(ctx, targets())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshAll(ctx, targets())
+		}
+	}
+}
+
+func (s *Scraper) refreshAll(ctx context.Context, targets []Target) {
+	for _, t := range targets {
+		stats := s.scrape(ctx, t)
+		s.mu.Lock()
+		s.stats[t.InfoHash] = stats
+		s.mu.Unlock()
+	}
+}
+
+// scrape queries the first reachable announce URL for seeder/leecher/
+// downloaded counts and HEAD-probes the torrent's web seeds.
+func (s *Scraper) scrape(ctx context.Context, t Target) TorrentStats {
+	stats := TorrentStats{InfoHash: t.InfoHash, FetchedAt: time.Now()}
+
+	for _, announce := range t.AnnounceURLs {
+		var (
+			seeders, leechers, downloaded int
+			err                           error
+		)
+
+		switch {
+		case strings.HasPrefix(announce, "udp://"):
+			seeders, leechers, downloaded, err = scrapeUDP(ctx, announce, t.InfoHash)
+		case strings.HasPrefix(announce, "http://"), strings.HasPrefix(announce, "https://"):
+			seeders, leechers, downloaded, err = s.scrapeHTTP(ctx, announce, t.InfoHash)
+		default:
+			continue
+		}
+
+		if err == nil {
+			stats.Seeders, stats.Leechers, stats.Downloaded = seeders, leechers, downloaded
+			break
+		}
+	}
+
+	stats.WebSeedAlive = s.probeWebSeeds(ctx, t.WebSeeds, t.Size)
+	return stats
+}
+
+// probeWebSeeds HEAD-probes each web seed and reports true if at least one
+// responds 200 OK with a Content-Length matching expectedSize. A non-positive
+// expectedSize (size unknown) skips the Content-Length check.
+func (s *Scraper) probeWebSeeds(ctx context.Context, webSeeds []string, expectedSize int64) bool {
+	for _, ws := range webSeeds {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, ws, nil)
+		if err != nil {
+			continue
+		}
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+		if expectedSize > 0 && resp.ContentLength != expectedSize {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// scrapeHTTP queries a tracker's HTTP scrape convention: the announce path's
+// final "announce" segment is replaced with "scrape", and info_hash is passed
+// as a raw 20-byte query parameter.
+func (s *Scraper) scrapeHTTP(ctx context.Context, announceURL, infoHashHex string) (seeders, leechers, downloaded int, err error) {
+	scrapeURL, err := scrapeURLFromAnnounce(announceURL)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	rawHash, err := hexToRawInfoHash(infoHashHex)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	q := scrapeURL.Query()
+	q.Set("info_hash", string(rawHash))
+	scrapeURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scrapeURL.String(), nil)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Files map[string]struct {
+			Complete   int `bencode:"complete"`
+			Incomplete int `bencode:"incomplete"`
+			Downloaded int `bencode:"downloaded"`
+		} `bencode:"files"`
+	}
+	if err := bencode.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, file := range body.Files {
+		return file.Complete, file.Incomplete, file.Downloaded, nil
+	}
+	return 0, 0, 0, fmt.Errorf("no scrape data returned for %s", infoHashHex)
+}
+
+// scrapeURLFromAnnounce turns an announce URL into its scrape counterpart
+// per the BitTorrent scrape convention.
+func scrapeURLFromAnnounce(announceURL string) (*url.URL, error) {
+	u, err := url.Parse(announceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := strings.LastIndex(u.Path, "/announce")
+	if idx == -1 {
+		return nil, fmt.Errorf("tracker does not support the scrape convention: %s", announceURL)
+	}
+	u.Path = u.Path[:idx] + "/scrape" + u.Path[idx+len("/announce"):]
+	return u, nil
+}
+
+// hexToRawInfoHash converts a 40-character hex info hash into its raw
+// 20-byte form, as required by both scrape conventions.
+func hexToRawInfoHash(infoHashHex string) ([]byte, error) {
+	if len(infoHashHex) != 40 {
+		return nil, fmt.Errorf("invalid info hash length: %s", infoHashHex)
+	}
+	raw := make([]byte, 20)
+	for i := 0; i < 20; i++ {
+		b, err := strconv.ParseUint(infoHashHex[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = byte(b)
+	}
+	return raw, nil
+}
+
+// udp tracker protocol (BEP-15) magic constants.
+const (
+	udpProtocolID  int64 = 0x41727101980
+	udpActionConn  int32 = 0
+	udpActionScrap int32 = 2
+)
+
+// scrapeUDP performs a BEP-15 connect+scrape exchange against a udp://
+// tracker and returns seeders/leechers/downloaded counts.
+func scrapeUDP(ctx context.Context, announceURL, infoHashHex string) (seeders, leechers, downloaded int, err error) {
+	u, err := url.Parse(announceURL)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	rawHash, err := hexToRawInfoHash(infoHashHex)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	conn, err := net.Dial("udp", u.Host)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	transactionID := rand.Int31()
+
+	connReq := make([]byte, 16)
+	binary.BigEndian.PutUint64(connReq[0:8], uint64(udpProtocolID))
+	binary.BigEndian.PutUint32(connReq[8:12], uint32(udpActionConn))
+	binary.BigEndian.PutUint32(connReq[12:16], uint32(transactionID))
+	if _, err := conn.Write(connReq); err != nil {
+		return 0, 0, 0, err
+	}
+
+	connResp := make([]byte, 16)
+	n, err := conn.Read(connResp)
+	if err != nil || n < 16 {
+		return 0, 0, 0, fmt.Errorf("udp connect failed: %v", err)
+	}
+	if int32(binary.BigEndian.Uint32(connResp[0:4])) != udpActionConn ||
+		int32(binary.BigEndian.Uint32(connResp[4:8])) != transactionID {
+		return 0, 0, 0, fmt.Errorf("unexpected udp connect response")
+	}
+	connectionID := binary.BigEndian.Uint64(connResp[8:16])
+
+	scrapeReq := make([]byte, 16+20)
+	binary.BigEndian.PutUint64(scrapeReq[0:8], connectionID)
+	binary.BigEndian.PutUint32(scrapeReq[8:12], uint32(udpActionScrap))
+	binary.BigEndian.PutUint32(scrapeReq[12:16], uint32(transactionID))
+	copy(scrapeReq[16:36], rawHash)
+	if _, err := conn.Write(scrapeReq); err != nil {
+		return 0, 0, 0, err
+	}
+
+	scrapeResp := make([]byte, 8+12)
+	n, err = conn.Read(scrapeResp)
+	if err != nil || n < 20 {
+		return 0, 0, 0, fmt.Errorf("udp scrape failed: %v", err)
+	}
+	if int32(binary.BigEndian.Uint32(scrapeResp[0:4])) != udpActionScrap ||
+		int32(binary.BigEndian.Uint32(scrapeResp[4:8])) != transactionID {
+		return 0, 0, 0, fmt.Errorf("unexpected udp scrape response")
+	}
+
+	seeders = int(binary.BigEndian.Uint32(scrapeResp[8:12]))
+	downloaded = int(binary.BigEndian.Uint32(scrapeResp[12:16]))
+	leechers = int(binary.BigEndian.Uint32(scrapeResp[16:20]))
+
+	return seeders, leechers, downloaded, nil
+}
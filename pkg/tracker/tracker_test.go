@@ -0,0 +1,49 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeWebSeedsRejectsMismatchedContentLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "999")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s := NewScraper(0)
+	if alive := s.probeWebSeeds(context.Background(), []string{server.URL}, 1024); alive {
+		t.Error("probeWebSeeds reported alive for a web seed whose Content-Length doesn't match the expected size")
+	}
+}
+
+func TestProbeWebSeedsAcceptsMatchingContentLength(t *testing.T) {
+	const size = 1024
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s := NewScraper(0)
+	if alive := s.probeWebSeeds(context.Background(), []string{server.URL}, size); !alive {
+		t.Error("probeWebSeeds reported dead for a web seed with a matching Content-Length")
+	}
+}
+
+func TestProbeWebSeedsSkipsContentLengthCheckWhenSizeUnknown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "999")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s := NewScraper(0)
+	if alive := s.probeWebSeeds(context.Background(), []string{server.URL}, 0); !alive {
+		t.Error("probeWebSeeds should treat a non-positive expectedSize as skipping the Content-Length check")
+	}
+}
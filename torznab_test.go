@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestTorznabServer starts an httptest server wired up the same way main()
+// wires the real one, pre-loaded with torrents so Prowlarr-style queries have
+// something to match.
+func newTestTorznabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	tm := NewTorrentManager(t.TempDir())
+	tm.index.Load().Put(TorrentInfo{
+		Name:     "The.Matrix.1999.1080p",
+		InfoHash: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		FilePath: "/torrents/The.Matrix.1999.1080p.torrent",
+		Category: 2000,
+		IMDBID:   "tt0133093",
+	})
+	tm.index.Load().Put(TorrentInfo{
+		Name:     "Some.Show.S01E02.720p",
+		InfoHash: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		FilePath: "/torrents/Some.Show.S01E02.720p.torrent",
+		Category: 5000,
+		TVDBID:   "12345",
+		Season:   1,
+		Episode:  2,
+	})
+
+	apiServer := NewAPIServer(tm, "http://example.invalid")
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/torznab", apiServer.handleTorznabAPI).Methods("GET")
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getTorznab(t *testing.T, server *httptest.Server, rawQuery string) []byte {
+	t.Helper()
+
+	resp, err := http.Get(server.URL + "/api/torznab?" + rawQuery)
+	if err != nil {
+		t.Fatalf("GET /api/torznab?%s: %v", rawQuery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/torznab?%s: status %s", rawQuery, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response body: %v", err)
+	}
+	return body
+}
+
+func TestTorznabCaps(t *testing.T) {
+	server := newTestTorznabServer(t)
+
+	body := getTorznab(t, server, "t=caps")
+
+	var caps struct {
+		XMLName    xml.Name `xml:"caps"`
+		Categories struct {
+			Category []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"category"`
+		} `xml:"categories"`
+	}
+	if err := xml.Unmarshal(body, &caps); err != nil {
+		t.Fatalf("unmarshaling caps response: %v\nbody: %s", err, body)
+	}
+	if len(caps.Categories.Category) == 0 {
+		t.Error("caps response advertised no categories")
+	}
+}
+
+func TestTorznabSearch(t *testing.T) {
+	server := newTestTorznabServer(t)
+
+	body := getTorznab(t, server, "t=search&q=Matrix")
+
+	var resp TorznabResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshaling search response: %v\nbody: %s", err, body)
+	}
+	if len(resp.Channel.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(resp.Channel.Items))
+	}
+	if resp.Channel.Items[0].Title != "The.Matrix.1999.1080p" {
+		t.Errorf("item title = %q, want The.Matrix.1999.1080p", resp.Channel.Items[0].Title)
+	}
+}
+
+func TestTorznabMovieSearchByIMDBID(t *testing.T) {
+	server := newTestTorznabServer(t)
+
+	body := getTorznab(t, server, "t=movie&imdbid=tt0133093")
+
+	var resp TorznabResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshaling movie search response: %v\nbody: %s", err, body)
+	}
+	if len(resp.Channel.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(resp.Channel.Items))
+	}
+
+	// Attributes isn't checked via resp.Channel.Items[0].Attributes: Go's
+	// encoding/xml treats the "torznab:" struct tag prefix as a namespace
+	// URI rather than a literal prefix, so it never matches the document's
+	// actual "torznab:attr" elements. Check the raw body instead.
+	if !strings.Contains(string(body), `name="imdb" value="0133093"`) {
+		t.Errorf("response missing imdb torznab:attr, body: %s", body)
+	}
+}
+
+func TestTorznabTVSearchBySeasonEpisode(t *testing.T) {
+	server := newTestTorznabServer(t)
+
+	body := getTorznab(t, server, "t=tvsearch&tvdbid=12345&season=1&ep=2")
+
+	var resp TorznabResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshaling tvsearch response: %v\nbody: %s", err, body)
+	}
+	if len(resp.Channel.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(resp.Channel.Items))
+	}
+	if resp.Channel.Items[0].Title != "Some.Show.S01E02.720p" {
+		t.Errorf("item title = %q, want Some.Show.S01E02.720p", resp.Channel.Items[0].Title)
+	}
+}
+
+func TestTorznabSearchByCategory(t *testing.T) {
+	server := newTestTorznabServer(t)
+
+	body := getTorznab(t, server, "t=search&cat=5000")
+
+	var resp TorznabResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unmarshaling search response: %v\nbody: %s", err, body)
+	}
+	if len(resp.Channel.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(resp.Channel.Items))
+	}
+	if resp.Channel.Items[0].Title != "Some.Show.S01E02.720p" {
+		t.Errorf("item title = %q, want Some.Show.S01E02.720p", resp.Channel.Items[0].Title)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/bencode"
+)
+
+func newTestUploadServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+
+	torrentsDir := t.TempDir()
+	tm := NewTorrentManager(torrentsDir)
+	apiServer := NewAPIServer(tm, "http://example.invalid")
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/torrents", apiServer.handleTorrentUpload).Methods("POST")
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, torrentsDir
+}
+
+func postTorrentUpload(t *testing.T, server *httptest.Server, filename string, content []byte) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("torrent", filename)
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatalf("writing form file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("closing multipart writer: %v", err)
+	}
+
+	resp, err := http.Post(server.URL+"/api/torrents", writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("POST /api/torrents: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestTorrentUploadRejectsInvalidBencodeWithoutWritingFile(t *testing.T) {
+	server, torrentsDir := newTestUploadServer(t)
+
+	resp := postTorrentUpload(t, server, "garbage.torrent", []byte("not a torrent file"))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	entries, err := os.ReadDir(torrentsDir)
+	if err != nil {
+		t.Fatalf("reading torrentsDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("torrentsDir has %d entries after a rejected upload, want 0 (found %v)", len(entries), entries)
+	}
+}
+
+func TestTorrentUploadAcceptsValidTorrent(t *testing.T) {
+	server, torrentsDir := newTestUploadServer(t)
+
+	valid, err := bencode.EncodeBytes(TorrentMetaInfo{
+		Announce: "http://tracker.test/announce",
+		Info: InfoDict{
+			Name:        "test.txt",
+			Length:      10,
+			PieceLength: 16384,
+			Pieces:      string(make([]byte, 20)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("encoding fixture torrent: %v", err)
+	}
+
+	resp := postTorrentUpload(t, server, "valid.torrent", valid)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if _, err := os.Stat(filepath.Join(torrentsDir, "valid.torrent")); err != nil {
+		t.Errorf("valid.torrent was not written to torrentsDir: %v", err)
+	}
+}